@@ -0,0 +1,308 @@
+/*
+ * Copyright (c) 2021 Anthony DeDominic <adedomin@gmail.com>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy of
+ * this software and associated documentation files (the "Software"), to deal in
+ * the Software without restriction, including without limitation the rights to
+ * use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+ * the Software, and to permit persons to whom the Software is furnished to do so,
+ * subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+ * FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+ * COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+ * IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+ * CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package treetextconf
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// A function that takes an encoder being constructed.
+// Returns error for invalid options
+type EncoderOptFunc func(e *Encoder) error
+
+type EncoderOptFuncError string
+
+func (e EncoderOptFuncError) Error() string {
+	return string(e)
+}
+
+// Sets the number of indent characters used per depth level.
+// example: treetextconf.NewEncoder(w, IndentWidth(4))
+func IndentWidth(width int) EncoderOptFunc {
+	return func(e *Encoder) error {
+		if width > 0 {
+			e.indentWidth = width
+			return nil
+		} else {
+			return EncoderOptFuncError("Indent width must be greater than 0.")
+		}
+	}
+}
+
+// Uses tabs instead of spaces to indent nested compound groups.
+// example: treetextconf.NewEncoder(w, IndentTabs())
+func IndentTabs() EncoderOptFunc {
+	return func(e *Encoder) error {
+		e.indentTab = true
+		return nil
+	}
+}
+
+// Sets a tree height guard on the encoder, mirroring Parser's HeightLimit.
+// example: treetextconf.NewEncoder(w, EncoderHeightLimit(10))
+func EncoderHeightLimit(limit int) EncoderOptFunc {
+	return func(e *Encoder) error {
+		if limit > 0 {
+			e.heightLimit = limit
+			return nil
+		} else {
+			return EncoderOptFuncError("Height limit must be greater than 0.")
+		}
+	}
+}
+
+// Sets a limit on how large (in bytes) an encoded configuration can be.
+// example: treetextconf.NewEncoder(w, EncoderSizeLimit(1024 * 16))
+func EncoderSizeLimit(limit int) EncoderOptFunc {
+	return func(e *Encoder) error {
+		if limit > 0 {
+			e.sizeLimit = limit
+			return nil
+		} else {
+			return EncoderOptFuncError("Size limit must be greater than 0.")
+		}
+	}
+}
+
+// Encoder renders a *Config tree back out as treetextconf text.
+type Encoder struct {
+	w           *bufio.Writer
+	indentWidth int
+	indentTab   bool
+	heightLimit int
+	sizeLimit   int
+	size        int
+}
+
+// Constructs a new encoder with defaults.
+// You can configure indentation and guards by passing the functions returned by:
+//
+//   - IndentWidth()        number of indent characters per depth level (default 2).
+//   - IndentTabs()         use tabs instead of spaces for indentation.
+//   - EncoderHeightLimit() maximum depth the encoder will render.
+//   - EncoderSizeLimit()   maximum size (in bytes) that the encoder will write.
+func NewEncoder(w io.Writer, options ...EncoderOptFunc) (*Encoder, error) {
+	e := &Encoder{
+		w: bufio.NewWriter(w),
+		indentWidth: 2,
+		indentTab: false,
+		heightLimit: -1,
+		sizeLimit: -1,
+	}
+
+	for _, option := range options {
+		if err := option(e); err != nil {
+			return nil, err
+		}
+	}
+
+	return e, nil
+}
+
+func (e *Encoder) checkHeight(height int) error {
+	if e.heightLimit != -1 {
+		if height > e.heightLimit {
+			return &ConfigError{
+				context: fmt.Sprintf("tree height exceeds limit: %d", e.heightLimit),
+				line: 0,
+				col: 0,
+			}
+		}
+	}
+
+	return nil
+}
+
+func (e *Encoder) checkSize() error {
+	if e.sizeLimit != -1 {
+		if e.size >= e.sizeLimit {
+			return &ConfigError{
+				context: fmt.Sprintf("size of config exceeds limit: %d", e.sizeLimit),
+				line: 0,
+				col: 0,
+			}
+		}
+	}
+
+	return nil
+}
+
+// escapeLine applies the same escaping rules the parser accepts: a leading
+// "'" to protect leading whitespace, a leading quote/hash, and a trailing "'"
+// to protect a trailing ':' or "'" from being read as a structural token.
+func escapeLine(content string) string {
+	if content == "" {
+		return "''"
+	}
+
+	var b strings.Builder
+	first := content[0]
+	if first == ' ' || first == '\t' || first == '\'' || first == '#' {
+		b.WriteByte('\'')
+	}
+	b.WriteString(content)
+	last := content[len(content)-1]
+	if last == ':' || last == '\'' {
+		b.WriteByte('\'')
+	}
+
+	return b.String()
+}
+
+// escapeCompoundName applies the parser's leading-marker escaping (and its
+// empty-name form) to a compound group's name. Unlike escapeLine it must
+// not also escape a trailing ':' or '\'': the caller appends the literal
+// terminator ':' right after, and the parser only ever strips that one
+// trailing colon before matching the name, so any colon or quote already
+// at the end of name survives untouched as ordinary content.
+func escapeCompoundName(name string) string {
+	if name == "" {
+		return "'"
+	}
+
+	first := name[0]
+	if first == ' ' || first == '\t' || first == '\'' || first == '#' {
+		return "'" + name
+	}
+
+	return name
+}
+
+// hasUnescapableColonSpace reports whether content contains a ": " sequence
+// anywhere in it. The parser's name/value scan (iterParse's nvPairNameEnd
+// search) looks for ": " anywhere on a line, not just where the encoder
+// intentionally places it, and there is no escape for it in the grammar -
+// neither the leading nor the trailing '\'' marker changes how that scan
+// runs. A leaf value containing ": " can therefore never round-trip.
+func hasUnescapableColonSpace(content string) bool {
+	return strings.Contains(content, ": ")
+}
+
+func errUnrepresentableValue(value string) error {
+	return fmt.Errorf(
+		"treetextconf: cannot encode %q: contains a \": \" sequence, which this format has no way to escape",
+		value,
+	)
+}
+
+func (e *Encoder) indent(depth int) string {
+	unit := " "
+	if e.indentTab {
+		unit = "\t"
+	}
+	return strings.Repeat(unit, e.indentWidth*depth)
+}
+
+func (e *Encoder) writeLine(content string, depth int) error {
+	line := e.indent(depth) + content + "\n"
+	e.size += len(line)
+	if err := e.checkSize(); err != nil {
+		return err
+	}
+	_, err := e.w.WriteString(line)
+	return err
+}
+
+func (e *Encoder) encodeChildren(node *Config, depth int) error {
+	if err := e.checkHeight(depth); err != nil {
+		return err
+	}
+
+	for _, child := range node.value {
+		switch {
+		// name: value shorthand, only when the sole child is itself a leaf
+		case len(child.value) == 1 && len(child.value[0].value) == 0:
+			value := child.value[0].name
+			if hasUnescapableColonSpace(child.name) {
+				return errUnrepresentableValue(child.name)
+			}
+			if hasUnescapableColonSpace(value) {
+				return errUnrepresentableValue(value)
+			}
+			line := child.name + ": " + value
+			if err := e.writeLine(escapeLine(line), depth); err != nil {
+				return err
+			}
+		// leaf value
+		case len(child.value) == 0:
+			if hasUnescapableColonSpace(child.name) {
+				return errUnrepresentableValue(child.name)
+			}
+			if err := e.writeLine(escapeLine(child.name), depth); err != nil {
+				return err
+			}
+		// compound group
+		default:
+			if err := e.writeLine(escapeCompoundName(child.name)+":", depth); err != nil {
+				return err
+			}
+			if err := e.encodeChildren(child, depth+1); err != nil {
+				return err
+			}
+			if err := e.writeLine(":", depth); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// Encode writes root's children out as treetextconf text.
+// root itself (typically the "__root__" node returned by ParseConfig) is not
+// rendered, mirroring how ParseConfig treats it as an implicit container.
+func (e *Encoder) Encode(root *Config) error {
+	if err := e.encodeChildren(root, 0); err != nil {
+		return err
+	}
+	return e.w.Flush()
+}
+
+// Marshal renders v as treetextconf text using an Encoder with default
+// options. v may be a *Config, in which case its children are rendered
+// directly, or a struct/map, in which case it is first converted to a
+// *Config tree using the same tag scheme as Decoder.
+func Marshal(v interface{}) ([]byte, error) {
+	root, ok := v.(*Config)
+	if !ok {
+		var err error
+		root, err = structToConfig(v)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var buf bytes.Buffer
+	enc, err := NewEncoder(&buf)
+	if err != nil {
+		return nil, err
+	}
+	if err := enc.Encode(root); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,145 @@
+/*
+ * Copyright (c) 2021 Anthony DeDominic <adedomin@gmail.com>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy of
+ * this software and associated documentation files (the "Software"), to deal in
+ * the Software without restriction, including without limitation the rights to
+ * use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+ * the Software, and to permit persons to whom the Software is furnished to do so,
+ * subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+ * FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+ * COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+ * IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+ * CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package treetextconf
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMarshalShorthand(t *testing.T) {
+	root := NewConfig("__root__", NewConfig("test", NewConfig("123")))
+
+	out, err := Marshal(root)
+	if err != nil {
+		t.Error(err)
+	}
+	assertEqual(t, string(out), "test: 123\n")
+}
+
+func TestMarshalCompound(t *testing.T) {
+	root := NewConfig("__root__",
+		NewConfig("test 123", NewConfig("xyz"), NewConfig("abc")),
+	)
+
+	out, err := Marshal(root)
+	if err != nil {
+		t.Error(err)
+	}
+	assertEqual(t, string(out), "test 123:\n  xyz\n  abc\n:\n")
+}
+
+func TestMarshalRoundTrip(t *testing.T) {
+	src := "test 123:\n  xyz\n  abc\n:"
+	parser, err := NewParser(strings.NewReader(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	root, err := parser.ParseConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := Marshal(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reparsed, err := NewParser(strings.NewReader(string(out)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	root2, err := reparsed.ParseConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertEqual(t, root2.value[0].name, root.value[0].name)
+	assertEqual(t, len(root2.value[0].value), len(root.value[0].value))
+}
+
+func TestMarshalCompoundNameEscaping(t *testing.T) {
+	names := []string{"  spaced", "", "'quoted", "#hashed", "trailing:", "trailing'"}
+
+	for _, name := range names {
+		// Two children, so this takes the compound-group branch rather
+		// than the name: value shorthand (which only fires for a sole
+		// leaf child).
+		root := NewConfig("__root__", NewConfig(name, NewConfig("xyz"), NewConfig("abc")))
+
+		out, err := Marshal(root)
+		if err != nil {
+			t.Fatalf("Marshal(%q): %v", name, err)
+		}
+
+		reparsed, err := NewParser(strings.NewReader(string(out)))
+		if err != nil {
+			t.Fatalf("NewParser for %q: %v", name, err)
+		}
+		root2, err := reparsed.ParseConfig()
+		if err != nil {
+			t.Fatalf("ParseConfig round-tripping %q (encoded as %q): %v", name, out, err)
+		}
+
+		if len(root2.value) != 1 {
+			t.Fatalf("round-tripping %q (encoded as %q): expected one child, got %d", name, out, len(root2.value))
+		}
+		assertEqual(t, root2.value[0].name, name)
+		if len(root2.value[0].value) != 2 {
+			t.Fatalf("round-tripping %q (encoded as %q): expected two grandchildren, got %d", name, out, len(root2.value[0].value))
+		}
+		assertEqual(t, root2.value[0].value[0].name, "xyz")
+		assertEqual(t, root2.value[0].value[1].name, "abc")
+	}
+}
+
+func TestMarshalRejectsUnescapableColonSpace(t *testing.T) {
+	root := NewConfig("__root__", NewConfig("note", NewConfig("see: details")))
+
+	if _, err := Marshal(root); err == nil {
+		t.Fatal("expected an error for a value containing an unescapable \": \" sequence")
+	}
+
+	root = NewConfig("__root__", NewConfig("see: details"))
+	if _, err := Marshal(root); err == nil {
+		t.Fatal("expected an error for a bare leaf containing an unescapable \": \" sequence")
+	}
+}
+
+func TestConfigAccessors(t *testing.T) {
+	child := NewConfig("child")
+	parent := NewConfig("parent", child)
+
+	assertEqual(t, parent.Name(), "parent")
+	assertEqual(t, len(parent.Children()), 1)
+
+	parent.SetName("renamed")
+	assertEqual(t, parent.Name(), "renamed")
+
+	other := NewConfig("other")
+	parent.AddChild(other)
+	assertEqual(t, len(parent.Children()), 2)
+
+	parent.RemoveChild(child)
+	assertEqual(t, len(parent.Children()), 1)
+	assertEqual(t, parent.Children()[0], other)
+}
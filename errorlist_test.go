@@ -0,0 +1,92 @@
+/*
+ * Copyright (c) 2021 Anthony DeDominic <adedomin@gmail.com>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy of
+ * this software and associated documentation files (the "Software"), to deal in
+ * the Software without restriction, including without limitation the rights to
+ * use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+ * the Software, and to permit persons to whom the Software is furnished to do so,
+ * subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+ * FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+ * COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+ * IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+ * CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package treetextconf
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseConfigCollectsMultipleErrors(t *testing.T) {
+	parser, err := NewParser(strings.NewReader("a:\n:\n:\nb:\n:\n:"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, parseErr := parser.ParseConfig()
+	list, ok := parseErr.(ConfigErrorList)
+	if !ok {
+		t.Fatalf("expected a ConfigErrorList, got %T: %v", parseErr, parseErr)
+	}
+	if len(list) != 2 {
+		t.Fatalf("expected 2 errors, got %d: %v", len(list), list)
+	}
+	assertEqual(t, list[0].line, 3)
+	assertEqual(t, list[1].line, 6)
+}
+
+func TestParseConfigStopOnFirstError(t *testing.T) {
+	parser, err := NewParser(strings.NewReader("a:\n:\n:\nb:\n:\n:"), StopOnFirstError())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, parseErr := parser.ParseConfig()
+	ce, ok := parseErr.(*ConfigError)
+	if !ok {
+		t.Fatalf("expected a single *ConfigError, got %T: %v", parseErr, parseErr)
+	}
+	assertEqual(t, ce.line, 3)
+}
+
+func TestUnterminatedGroupReportsOpeningPosition(t *testing.T) {
+	parser, err := NewParser(strings.NewReader("test 123:\n  xyz\n  abc"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, parseErr := parser.ParseConfig()
+	list, ok := parseErr.(ConfigErrorList)
+	if !ok {
+		t.Fatalf("expected a ConfigErrorList, got %T: %v", parseErr, parseErr)
+	}
+	if len(list) != 1 {
+		t.Fatalf("expected 1 error, got %d: %v", len(list), list)
+	}
+	assertEqual(t, list[0].line, 1)
+	assertEqual(t, list[0].col, 1)
+}
+
+func TestConfigErrorListSort(t *testing.T) {
+	list := ConfigErrorList{
+		{context: "b", line: 2, col: 1},
+		{context: "a", line: 1, col: 5},
+	}
+	list.Sort()
+	assertEqual(t, list[0].context, "a")
+	assertEqual(t, list[1].context, "b")
+
+	var empty ConfigErrorList
+	if empty.Err() != nil {
+		t.Error("expected Err() to return nil for an empty list")
+	}
+}
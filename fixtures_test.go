@@ -0,0 +1,145 @@
+/*
+ * Copyright (c) 2021 Anthony DeDominic <adedomin@gmail.com>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy of
+ * this software and associated documentation files (the "Software"), to deal in
+ * the Software without restriction, including without limitation the rights to
+ * use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+ * the Software, and to permit persons to whom the Software is furnished to do so,
+ * subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+ * FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+ * COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+ * IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+ * CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package treetextconf
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// A fixture line ending in `# ERROR "regex"` declares that parsing that line
+// (once the marker itself is stripped) must produce a *ConfigError whose
+// context matches regex. Modeled on go/parser's error_test.go fixtures.
+var errorMarkerRe = regexp.MustCompile(`^(.*?)\s*#\s*ERROR\s+"((?:[^"\\]|\\.)*)"\s*$`)
+
+var heightLimitFileRe = regexp.MustCompile(`^height_limit_(\d+)\.tconf$`)
+var sizeLimitFileRe = regexp.MustCompile(`^size_limit_(\d+)\.tconf$`)
+
+// stripMarkers reads a fixture, returning its content with every ERROR
+// marker removed (so byte/line offsets of real content are unaffected) and
+// the line -> expected-message-regex it recorded.
+func stripMarkers(t *testing.T, path string) (string, map[int]*regexp.Regexp) {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(string(data), "\n")
+	wanted := make(map[int]*regexp.Regexp)
+	for i, line := range lines {
+		m := errorMarkerRe.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		re, err := regexp.Compile(m[2])
+		if err != nil {
+			t.Fatalf("%s:%d: invalid ERROR regex %q: %s", path, i+1, m[2], err)
+		}
+		wanted[i+1] = re
+		lines[i] = m[1]
+	}
+
+	return strings.Join(lines, "\n"), wanted
+}
+
+// fixtureOptions derives ParserOptFunc from well-known filename patterns, so
+// a fixture can exercise HeightLimit/SizeLimit without a bespoke test case.
+func fixtureOptions(name string) []ParserOptFunc {
+	if m := heightLimitFileRe.FindStringSubmatch(name); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		return []ParserOptFunc{HeightLimit(n)}
+	}
+	if m := sizeLimitFileRe.FindStringSubmatch(name); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		return []ParserOptFunc{SizeLimit(n)}
+	}
+	return nil
+}
+
+// fixtureErrors normalizes whatever ParseConfig returned into the list of
+// *ConfigError it represents.
+func fixtureErrors(err error) []*ConfigError {
+	if err == nil {
+		return nil
+	}
+	if list, ok := err.(ConfigErrorList); ok {
+		return list.Errors()
+	}
+	if ce, ok := err.(*ConfigError); ok {
+		return []*ConfigError{ce}
+	}
+	return nil
+}
+
+// TestFixtures walks testdata/*.tconf, parses each with its markers
+// stripped, and checks that the (line, regex) pairs recorded by
+// stripMarkers correspond one-to-one with the *ConfigError values
+// ParseConfig produced: nothing missing, nothing unexpected, nothing on the
+// wrong line.
+func TestFixtures(t *testing.T) {
+	matches, err := filepath.Glob("testdata/*.tconf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) == 0 {
+		t.Fatal("no fixtures found under testdata/")
+	}
+
+	for _, path := range matches {
+		path := path
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			input, wanted := stripMarkers(t, path)
+
+			parser, err := NewParser(strings.NewReader(input), fixtureOptions(filepath.Base(path))...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, parseErr := parser.ParseConfig()
+
+			got := fixtureErrors(parseErr)
+			for line, re := range wanted {
+				idx := -1
+				for i, ce := range got {
+					if ce.line == line && re.MatchString(ce.context) {
+						idx = i
+						break
+					}
+				}
+				if idx == -1 {
+					t.Errorf("missing expected error at line %d matching %q", line, re.String())
+					continue
+				}
+				got = append(got[:idx], got[idx+1:]...)
+			}
+
+			for _, ce := range got {
+				t.Errorf("unexpected error at line %d: %s", ce.line, ce.context)
+			}
+		})
+	}
+}
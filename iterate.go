@@ -0,0 +1,103 @@
+/*
+ * Copyright (c) 2021 Anthony DeDominic <adedomin@gmail.com>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy of
+ * this software and associated documentation files (the "Software"), to deal in
+ * the Software without restriction, including without limitation the rights to
+ * use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+ * the Software, and to permit persons to whom the Software is furnished to do so,
+ * subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+ * FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+ * COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+ * IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+ * CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package treetextconf
+
+import (
+	"errors"
+	"iter"
+)
+
+// Returned by a Walk callback to prune node's children without stopping the
+// walk, mirroring filepath.SkipDir.
+var SkipSubtree = errors.New("treetextconf: skip subtree")
+
+// Walk performs a pre-order traversal of root and everything beneath it,
+// calling fn for each node with its depth (root is 0) and the path of names
+// leading to it (root's own path is empty). If fn returns SkipSubtree, node's
+// children are skipped but the walk continues; any other non-nil error
+// stops the walk and is returned as-is.
+func Walk(root *Config, fn func(node *Config, depth int, path []string) error) error {
+	return walk(root, 0, nil, fn)
+}
+
+func walk(node *Config, depth int, path []string, fn func(*Config, int, []string) error) error {
+	if err := fn(node, depth, path); err != nil {
+		if err == SkipSubtree {
+			return nil
+		}
+		return err
+	}
+
+	for _, child := range node.value {
+		childPath := append(append([]string{}, path...), child.name)
+		if err := walk(child, depth+1, childPath, fn); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// All returns a pull-style, pre-order iterator over c and everything
+// beneath it, yielding (path, node) pairs the same way Walk does. Intended
+// for range-over-func: for path, node := range c.All() { ... }.
+func (c *Config) All() iter.Seq2[[]string, *Config] {
+	return func(yield func([]string, *Config) bool) {
+		var visit func(node *Config, path []string) bool
+		visit = func(node *Config, path []string) bool {
+			if !yield(path, node) {
+				return false
+			}
+			for _, child := range node.value {
+				childPath := append(append([]string{}, path...), child.name)
+				if !visit(child, childPath) {
+					return false
+				}
+			}
+			return true
+		}
+		visit(c, nil)
+	}
+}
+
+// Lookup walks path one name at a time, returning the first child matching
+// that name at each level, so callers can write cfg.Lookup("server",
+// "listen") instead of manually walking Children(). Returns nil if any
+// segment has no match.
+func (c *Config) Lookup(path ...string) *Config {
+	cur := c
+	for _, name := range path {
+		var next *Config
+		for _, child := range cur.value {
+			if child.name == name {
+				next = child
+				break
+			}
+		}
+		if next == nil {
+			return nil
+		}
+		cur = next
+	}
+
+	return cur
+}
@@ -0,0 +1,97 @@
+/*
+ * Copyright (c) 2021 Anthony DeDominic <adedomin@gmail.com>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy of
+ * this software and associated documentation files (the "Software"), to deal in
+ * the Software without restriction, including without limitation the rights to
+ * use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+ * the Software, and to permit persons to whom the Software is furnished to do so,
+ * subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+ * FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+ * COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+ * IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+ * CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package treetextconf
+
+import (
+	"strings"
+	"testing"
+)
+
+func buildTestTree() *Config {
+	return NewConfig("__root__",
+		NewConfig("server",
+			NewConfig("listen", NewConfig("0.0.0.0")),
+			NewConfig("port", NewConfig("8080")),
+		),
+		NewConfig("debug", NewConfig("true")),
+	)
+}
+
+func TestWalk(t *testing.T) {
+	root := buildTestTree()
+
+	var names []string
+	err := Walk(root, func(node *Config, depth int, path []string) error {
+		names = append(names, node.name)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertEqual(t, strings.Join(names, ","), "__root__,server,listen,0.0.0.0,port,8080,debug,true")
+}
+
+func TestWalkSkipSubtree(t *testing.T) {
+	root := buildTestTree()
+
+	var names []string
+	err := Walk(root, func(node *Config, depth int, path []string) error {
+		names = append(names, node.name)
+		if node.name == "server" {
+			return SkipSubtree
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertEqual(t, strings.Join(names, ","), "__root__,server,debug,true")
+}
+
+func TestAll(t *testing.T) {
+	root := buildTestTree()
+
+	count := 0
+	for path, node := range root.All() {
+		count++
+		if node.name == "listen" {
+			assertEqual(t, strings.Join(path, "/"), "server/listen")
+		}
+	}
+	assertEqual(t, count, 8)
+}
+
+func TestLookup(t *testing.T) {
+	root := buildTestTree()
+
+	listen := root.Lookup("server", "listen")
+	if listen == nil {
+		t.Fatal("expected to find server/listen")
+	}
+	assertEqual(t, listen.value[0].name, "0.0.0.0")
+
+	if root.Lookup("server", "bogus") != nil {
+		t.Error("expected no match for server/bogus")
+	}
+}
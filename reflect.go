@@ -0,0 +1,348 @@
+/*
+ * Copyright (c) 2021 Anthony DeDominic <adedomin@gmail.com>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy of
+ * this software and associated documentation files (the "Software"), to deal in
+ * the Software without restriction, including without limitation the rights to
+ * use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+ * the Software, and to permit persons to whom the Software is furnished to do so,
+ * subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+ * FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+ * COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+ * IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+ * CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package treetextconf
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Struct tag key read when mapping tree nodes onto Go values.
+// example: `treetextconf:"listen"`
+const tagKey = "treetextconf"
+
+// A function that takes a decoder being constructed.
+// Returns error for invalid options
+type DecoderOptFunc func(d *Decoder) error
+
+// Rejects tree nodes that don't correspond to a known struct field instead of
+// silently ignoring them.
+// example: treetextconf.NewDecoder(DisallowUnknownFields())
+func DisallowUnknownFields() DecoderOptFunc {
+	return func(d *Decoder) error {
+		d.disallowUnknown = true
+		return nil
+	}
+}
+
+// Decoder maps a *Config tree onto a Go struct or map via reflection.
+type Decoder struct {
+	disallowUnknown bool
+}
+
+// Constructs a new decoder with defaults.
+func NewDecoder(options ...DecoderOptFunc) (*Decoder, error) {
+	d := &Decoder{}
+
+	for _, option := range options {
+		if err := option(d); err != nil {
+			return nil, err
+		}
+	}
+
+	return d, nil
+}
+
+// Decode maps root's children onto v, which must be a non-nil pointer to a
+// struct or map.
+func (d *Decoder) Decode(root *Config, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("treetextconf: Decode requires a non-nil pointer, got %T", v)
+	}
+
+	return d.decodeNode(root, rv.Elem())
+}
+
+// Decode maps c's children onto v using a Decoder with default options.
+// See Decoder.Decode for the mapping rules.
+func (c *Config) Decode(v interface{}) error {
+	d, err := NewDecoder()
+	if err != nil {
+		return err
+	}
+	return d.Decode(c, v)
+}
+
+// Unmarshal parses data and decodes the result onto v, which must be a
+// non-nil pointer to a struct or map.
+func Unmarshal(data []byte, v interface{}) error {
+	parser, err := NewParser(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	root, err := parser.ParseConfig()
+	if err != nil {
+		return err
+	}
+
+	return root.Decode(v)
+}
+
+func fieldName(f reflect.StructField) string {
+	if name := f.Tag.Get(tagKey); name != "" {
+		return name
+	}
+	return strings.ToLower(f.Name)
+}
+
+func (d *Decoder) decodeNode(node *Config, rv reflect.Value) error {
+	if rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		return d.decodeNode(node, rv.Elem())
+	}
+
+	switch rv.Kind() {
+	case reflect.Struct:
+		return d.decodeStruct(node, rv)
+	case reflect.Map:
+		return d.decodeMap(node, rv)
+	default:
+		return d.decodeScalar(node, rv)
+	}
+}
+
+func (d *Decoder) decodeScalar(node *Config, rv reflect.Value) error {
+	value := node.name
+	if len(node.value) == 1 && len(node.value[0].value) == 0 {
+		value = node.value[0].name
+	}
+
+	switch rv.Kind() {
+	case reflect.String:
+		rv.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		rv.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return err
+		}
+		rv.SetInt(i)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return err
+		}
+		rv.SetFloat(f)
+	default:
+		return fmt.Errorf("treetextconf: unsupported field kind %s", rv.Kind())
+	}
+
+	return nil
+}
+
+func (d *Decoder) decodeMap(node *Config, rv reflect.Value) error {
+	if rv.Type().Key().Kind() != reflect.String {
+		return fmt.Errorf("treetextconf: unsupported map key kind %s, only string keys are supported", rv.Type().Key().Kind())
+	}
+
+	if rv.IsNil() {
+		rv.Set(reflect.MakeMap(rv.Type()))
+	}
+
+	elemType := rv.Type().Elem()
+	for _, child := range node.value {
+		elem := reflect.New(elemType).Elem()
+		if err := d.decodeNode(child, elem); err != nil {
+			return err
+		}
+		rv.SetMapIndex(reflect.ValueOf(child.name), elem)
+	}
+
+	return nil
+}
+
+func (d *Decoder) decodeStruct(node *Config, rv reflect.Value) error {
+	t := rv.Type()
+	fields := make(map[string]int, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).PkgPath != "" { // unexported
+			continue
+		}
+		fields[fieldName(t.Field(i))] = i
+	}
+
+	grouped := make(map[string][]*Config)
+	var order []string
+	for _, child := range node.value {
+		if _, ok := grouped[child.name]; !ok {
+			order = append(order, child.name)
+		}
+		grouped[child.name] = append(grouped[child.name], child)
+	}
+
+	for _, name := range order {
+		children := grouped[name]
+		idx, ok := fields[name]
+		if !ok {
+			if d.disallowUnknown {
+				return fmt.Errorf("treetextconf: unknown field %q", name)
+			}
+			continue
+		}
+
+		fv := rv.Field(idx)
+		if fv.Kind() == reflect.Slice {
+			slice := reflect.MakeSlice(fv.Type(), 0, len(children))
+			for _, child := range children {
+				elem := reflect.New(fv.Type().Elem()).Elem()
+				if err := d.decodeNode(child, elem); err != nil {
+					return err
+				}
+				slice = reflect.Append(slice, elem)
+			}
+			fv.Set(slice)
+			continue
+		}
+
+		if err := d.decodeNode(children[len(children)-1], fv); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// scalarString renders v (a non-struct, non-map, non-slice value) as the
+// literal text stored in a leaf node.
+func scalarString(rv reflect.Value) (string, error) {
+	switch rv.Kind() {
+	case reflect.String:
+		return rv.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(rv.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(rv.Float(), 'g', -1, 64), nil
+	default:
+		return "", fmt.Errorf("treetextconf: unsupported field kind %s", rv.Kind())
+	}
+}
+
+func valueToConfig(name string, rv reflect.Value) (*Config, error) {
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return NewConfig(name), nil
+		}
+		rv = rv.Elem()
+	}
+
+	switch rv.Kind() {
+	case reflect.Struct:
+		node := NewConfig(name)
+		if err := appendStructFields(node, rv); err != nil {
+			return nil, err
+		}
+		return node, nil
+	case reflect.Map:
+		node := NewConfig(name)
+		for _, key := range rv.MapKeys() {
+			child, err := valueToConfig(fmt.Sprint(key.Interface()), rv.MapIndex(key))
+			if err != nil {
+				return nil, err
+			}
+			node.addValue(child)
+		}
+		return node, nil
+	default:
+		value, err := scalarString(rv)
+		if err != nil {
+			return nil, err
+		}
+		return NewConfig(name, NewConfig(value)), nil
+	}
+}
+
+func appendStructFields(node *Config, rv reflect.Value) error {
+	t := rv.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" { // unexported
+			continue
+		}
+		name := fieldName(f)
+
+		fv := rv.Field(i)
+		if fv.Kind() == reflect.Slice {
+			for j := 0; j < fv.Len(); j++ {
+				child, err := valueToConfig(name, fv.Index(j))
+				if err != nil {
+					return err
+				}
+				node.addValue(child)
+			}
+			continue
+		}
+
+		child, err := valueToConfig(name, fv)
+		if err != nil {
+			return err
+		}
+		node.addValue(child)
+	}
+
+	return nil
+}
+
+// structToConfig builds a *Config tree ("__root__" with one child per field,
+// one per element of a slice field, or one per key of a map) from a struct
+// or map, using the same tag scheme as Decoder.
+func structToConfig(v interface{}) (*Config, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return NewConfig("__root__"), nil
+		}
+		rv = rv.Elem()
+	}
+
+	root := NewConfig("__root__")
+	switch rv.Kind() {
+	case reflect.Struct:
+		if err := appendStructFields(root, rv); err != nil {
+			return nil, err
+		}
+	case reflect.Map:
+		for _, key := range rv.MapKeys() {
+			child, err := valueToConfig(fmt.Sprint(key.Interface()), rv.MapIndex(key))
+			if err != nil {
+				return nil, err
+			}
+			root.addValue(child)
+		}
+	default:
+		return nil, fmt.Errorf("treetextconf: Marshal requires a struct, map, or *Config, got %T", v)
+	}
+
+	return root, nil
+}
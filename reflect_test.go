@@ -0,0 +1,157 @@
+/*
+ * Copyright (c) 2021 Anthony DeDominic <adedomin@gmail.com>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy of
+ * this software and associated documentation files (the "Software"), to deal in
+ * the Software without restriction, including without limitation the rights to
+ * use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+ * the Software, and to permit persons to whom the Software is furnished to do so,
+ * subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+ * FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+ * COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+ * IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+ * CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package treetextconf
+
+import (
+	"strings"
+	"testing"
+)
+
+type serverConfig struct {
+	Listen string `treetextconf:"listen"`
+	Port   int    `treetextconf:"port"`
+	Debug  bool   `treetextconf:"debug"`
+	Tags   []string
+}
+
+func TestUnmarshalStruct(t *testing.T) {
+	src := []byte("server:\n  listen: 0.0.0.0\n  port: 8080\n  debug: true\n  tags: alpha\n  tags: beta\n:")
+
+	var cfg struct {
+		Server serverConfig
+	}
+	if err := Unmarshal(src, &cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	assertEqual(t, cfg.Server.Listen, "0.0.0.0")
+	assertEqual(t, cfg.Server.Port, 8080)
+	assertEqual(t, cfg.Server.Debug, true)
+	assertEqual(t, len(cfg.Server.Tags), 2)
+	assertEqual(t, cfg.Server.Tags[0], "alpha")
+	assertEqual(t, cfg.Server.Tags[1], "beta")
+}
+
+func TestUnmarshalUnknownFields(t *testing.T) {
+	src := []byte("server:\n  listen: 0.0.0.0\n  bogus: nope\n:")
+
+	var cfg struct {
+		Server struct {
+			Listen string `treetextconf:"listen"`
+		}
+	}
+
+	// unknown fields are ignored by default
+	if err := Unmarshal(src, &cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	d, err := NewDecoder(DisallowUnknownFields())
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := NewParser(strings.NewReader(string(src)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	root, err := p.ParseConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Decode(root, &cfg); err == nil {
+		t.Error("expected an error for an unknown field with DisallowUnknownFields set")
+	}
+}
+
+func TestUnmarshalMap(t *testing.T) {
+	src := []byte("tags:\n  alpha: one\n  beta: two\n:")
+
+	var cfg struct {
+		Tags map[string]string
+	}
+	if err := Unmarshal(src, &cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	assertEqual(t, len(cfg.Tags), 2)
+	assertEqual(t, cfg.Tags["alpha"], "one")
+	assertEqual(t, cfg.Tags["beta"], "two")
+}
+
+func TestMarshalMap(t *testing.T) {
+	cfg := struct {
+		Tags map[string]string
+	}{
+		Tags: map[string]string{"alpha": "one"},
+	}
+
+	out, err := Marshal(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var roundTrip struct {
+		Tags map[string]string
+	}
+	if err := Unmarshal(out, &roundTrip); err != nil {
+		t.Fatal(err)
+	}
+
+	assertEqual(t, roundTrip.Tags["alpha"], "one")
+}
+
+func TestUnmarshalNonStringMapKeyReturnsError(t *testing.T) {
+	src := []byte("tags:\n  1: one\n:")
+
+	var cfg struct {
+		Tags map[int]string
+	}
+	if err := Unmarshal(src, &cfg); err == nil {
+		t.Fatal("expected an error decoding into a non-string-keyed map, not a panic")
+	}
+}
+
+func TestMarshalStruct(t *testing.T) {
+	cfg := struct {
+		Server serverConfig
+	}{
+		Server: serverConfig{
+			Listen: "0.0.0.0",
+			Port:   8080,
+		},
+	}
+
+	out, err := Marshal(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var roundTrip struct {
+		Server serverConfig
+	}
+	if err := Unmarshal(out, &roundTrip); err != nil {
+		t.Fatal(err)
+	}
+
+	assertEqual(t, roundTrip.Server.Listen, cfg.Server.Listen)
+	assertEqual(t, roundTrip.Server.Port, cfg.Server.Port)
+}
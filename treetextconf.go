@@ -25,6 +25,7 @@ import (
 	"bufio"
 	"fmt"
 	"io"
+	"sort"
 	"strings"
 )
 
@@ -40,6 +41,46 @@ func (c *Config) addValue(v *Config) {
 	c.value = append(c.value, v)
 }
 
+// Constructs a new Config node with the given name and, optionally, children.
+// Useful for building a tree programmatically ahead of a call to Marshal/Encode.
+func NewConfig(name string, children ...*Config) *Config {
+	return &Config{
+		name: name,
+		value: children,
+	}
+}
+
+// Name returns the node's name (or its value, for a leaf node).
+func (c *Config) Name() string {
+	return c.name
+}
+
+// SetName replaces the node's name (or its value, for a leaf node).
+func (c *Config) SetName(name string) {
+	c.name = name
+}
+
+// Children returns the node's direct children.
+func (c *Config) Children() []*Config {
+	return c.value
+}
+
+// AddChild appends a child node, equivalent to addValue but exported for callers
+// building a tree outside the package.
+func (c *Config) AddChild(child *Config) {
+	c.addValue(child)
+}
+
+// RemoveChild removes the first child matching the given pointer, if present.
+func (c *Config) RemoveChild(child *Config) {
+	for i, v := range c.value {
+		if v == child {
+			c.value = append(c.value[:i], c.value[i+1:]...)
+			return
+		}
+	}
+}
+
 // Error with a message, line and column context
 type ConfigError struct {
 	context string
@@ -54,6 +95,50 @@ func (e *ConfigError) Error() string {
 	)
 }
 
+// ConfigErrorList collects every *ConfigError a single ParseConfig pass
+// produced. Modeled on go/scanner.ErrorList: sortable by position, and
+// Err() collapses an empty list back down to nil so callers can keep
+// writing `if err != nil`.
+type ConfigErrorList []*ConfigError
+
+func (l ConfigErrorList) Error() string {
+	switch len(l) {
+	case 0:
+		return "no errors"
+	case 1:
+		return l[0].Error()
+	default:
+		return fmt.Sprintf("%s (and %d more errors)", l[0], len(l)-1)
+	}
+}
+
+// Errors returns the individual errors that make up the list.
+func (l ConfigErrorList) Errors() []*ConfigError {
+	return l
+}
+
+func (l ConfigErrorList) Len() int      { return len(l) }
+func (l ConfigErrorList) Swap(i, j int) { l[i], l[j] = l[j], l[i] }
+func (l ConfigErrorList) Less(i, j int) bool {
+	if l[i].line != l[j].line {
+		return l[i].line < l[j].line
+	}
+	return l[i].col < l[j].col
+}
+
+// Sort orders the list by line, then column.
+func (l ConfigErrorList) Sort() {
+	sort.Sort(l)
+}
+
+// Err returns l as an error, or nil if l is empty.
+func (l ConfigErrorList) Err() error {
+	if len(l) == 0 {
+		return nil
+	}
+	return l
+}
+
 // A function that takes a parser being constructed.
 // Returns error for invalid options
 type ParserOptFunc func(p *Parser) error
@@ -99,13 +184,26 @@ type Parser struct {
 	size int
 	heightLimit int
 	sizeLimit int
+	stopOnFirstError bool
+}
+
+// Stops ParseConfig at the first error it finds, returning it directly,
+// instead of the default behaviour of recovering where possible and
+// returning every error found via a ConfigErrorList.
+// example: treetextconf.NewParser(file, StopOnFirstError())
+func StopOnFirstError() ParserOptFunc {
+	return func(p *Parser) error {
+		p.stopOnFirstError = true
+		return nil
+	}
 }
 
 // Constructs a new parser with defaults.
 // You can configure heightLimit and sizeLimit by passing the functions returned by:
 //
-//   - HeightLimit() maximum depth the parser will go.
-//   - SizeLimit()   maxium size (in bytes) that the parser will read.
+//   - HeightLimit()       maximum depth the parser will go.
+//   - SizeLimit()         maxium size (in bytes) that the parser will read.
+//   - StopOnFirstError()  abort on the first error instead of collecting every one.
 func NewParser(content io.Reader, options ...ParserOptFunc) (*Parser, error) {
 	h := &Parser{
 		content: bufio.NewReader(content),
@@ -184,11 +282,22 @@ func (p *Parser) nextLine() error {
 	return nil
 }
 
+// A single open compound group on the parse stack, along with the position
+// it was opened at, so an unterminated group can be reported where it
+// started rather than at EOF.
+type parseFrame struct {
+	node *Config
+	line int
+	col int
+}
+
 func (p *Parser) iterParse(root *Config) error {
+	var errs ConfigErrorList
+
 	var err error
-	stack := []*Config{root}
-	c := stack[len(stack) - 1]
-	
+	stack := []parseFrame{{node: root}}
+	c := stack[len(stack) - 1].node
+
 out:
 	for err = p.nextLine(); err == nil; err = p.nextLine() {
 		i := 0
@@ -247,16 +356,26 @@ out:
 			if start == end && !foundContentStart {
 				stack = stack[:len(stack)-1]
 				if (len(stack) == 0) {
-					break out // too many '\n:'
+					errs = append(errs, &ConfigError{
+						context: "Too many compound terminators ':'",
+						line: p.lineno,
+						col: start + 1,
+					})
+					if p.stopOnFirstError {
+						break out
+					}
+					// recover: treat the rest of the file as if it were
+					// back at the root, rather than aborting outright.
+					stack = []parseFrame{{node: root}}
 				}
-				c = stack[len(stack)-1]
+				c = stack[len(stack)-1].node
 			} else {
 				c.addValue(newConf)
 				newConf.name = string(p.line[start:end])
-				stack = append(stack, newConf)
+				stack = append(stack, parseFrame{node: newConf, line: p.lineno, col: start + 1})
 				c = newConf
-				err = p.checkHeight(len(stack)-1)
-				if err != nil {
+				if e := p.checkHeight(len(stack) - 1); e != nil {
+					errs = append(errs, e.(*ConfigError))
 					break out // tree too big
 				}
 			}
@@ -275,29 +394,42 @@ out:
 		}
 	}
 
-	if err == io.EOF && len(stack) > 1 {
-		return &ConfigError{
-			context: "Unterminated compound group, not enough ':'",
-			line: p.lineno,
-			col: 0,
+	switch {
+	case err == io.EOF && len(stack) > 1:
+		// synthesize the missing closes: report each still-open group at
+		// the position it was opened, rather than at EOF.
+		for _, f := range stack[1:] {
+			errs = append(errs, &ConfigError{
+				context: "Unterminated compound group, not enough ':'",
+				line: f.line,
+				col: f.col,
+			})
+			if p.stopOnFirstError {
+				break
+			}
 		}
-	} else if len(stack) == 0 {
-		return &ConfigError{
-			context: "Too many compound terminators ':'",
-			line: p.lineno,
-			col: 0,
+	case err != nil && err != io.EOF:
+		if ce, ok := err.(*ConfigError); ok {
+			errs = append(errs, ce)
+		} else {
+			return err
 		}
-	} else if err != io.EOF {
-		return err
-	} else {
-		return nil
 	}
+
+	if p.stopOnFirstError && len(errs) > 0 {
+		return errs[0]
+	}
+	return errs.Err()
 }
 
 // Executes the constructed parser returning a config.
 // config.value will contain your configuration file's parsed contents.
 // config.name == "__root__" which is the default root node, even if the file
 // is empty.
+// The returned error is a ConfigErrorList covering every problem found in
+// one pass, unless StopOnFirstError() was set, in which case it is the
+// single *ConfigError that stopped parsing. It is nil when nothing was
+// wrong, and an error from reading content is returned as-is.
 func (p *Parser) ParseConfig() (*Config, error) {
 	root := &Config{
 		name: "__root__",
@@ -319,5 +451,3 @@ func DebugPrintConfig(root *Config, depth int) {
 		DebugPrintConfig(v, depth+1)
 	}
 }
-
-// TODO: Add Iterator?
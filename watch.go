@@ -0,0 +1,224 @@
+/*
+ * Copyright (c) 2021 Anthony DeDominic <adedomin@gmail.com>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy of
+ * this software and associated documentation files (the "Software"), to deal in
+ * the Software without restriction, including without limitation the rights to
+ * use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+ * the Software, and to permit persons to whom the Software is furnished to do so,
+ * subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+ * FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+ * COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+ * IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+ * CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package treetextconf
+
+import (
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// Default coalescing window used to collapse a burst of filesystem events
+// (editors routinely emit several writes for a single save) into one reload.
+const DefaultDebounceWindow = 100 * time.Millisecond
+
+// Watcher wraps a file path, re-parsing it with ParseConfig whenever it
+// changes on disk and exposing the latest successfully parsed tree.
+type Watcher struct {
+	path        string
+	parserOpts  []ParserOptFunc
+	debounceNs  atomic.Int64
+	fsw         *fsnotify.Watcher
+	current     atomic.Pointer[Config]
+	mu          sync.Mutex
+	subscribers []func(old, new *Config, err error)
+	notified    bool // true once the first reload (initial or otherwise) has run
+	lastOld     *Config
+	lastNew     *Config
+	lastErr     error
+	done        chan struct{}
+	closeOnce   sync.Once
+}
+
+// New does an initial parse of path and then starts watching it for
+// further changes, re-parsing on each one. The initial parse happens
+// synchronously, before New returns, so Current() and a subsequently
+// registered OnChange subscriber both observe it reliably — neither races
+// a caller that modifies path right after New returns. opts configure the
+// Parser used for every (re)load, e.g. HeightLimit/SizeLimit.
+//
+// A failed parse, initial or otherwise, does not clobber the last-good
+// tree: Current() keeps returning the previous result (nil, for an initial
+// failure), and the failure is only visible to OnChange subscribers.
+//
+// path is assumed to be an ordinary file: New blocks for as long as
+// opening and parsing it takes, so a path backed by something that can
+// stall a read indefinitely (a FIFO with no writer, a wedged network
+// mount) will make New block indefinitely too.
+func New(path string, opts ...ParserOptFunc) (*Watcher, error) {
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	if err := fsw.Add(path); err != nil {
+		fsw.Close()
+		return nil, err
+	}
+
+	w := &Watcher{
+		path:       path,
+		parserOpts: opts,
+		fsw:        fsw,
+		done:       make(chan struct{}),
+	}
+	w.debounceNs.Store(int64(DefaultDebounceWindow))
+
+	w.reload() // seed Current(), ignoring an initial parse error
+
+	go w.run()
+
+	return w, nil
+}
+
+// SetDebounceWindow changes how long the watcher waits for a burst of
+// filesystem events to settle before re-parsing. Safe to call concurrently
+// with the watcher's background goroutine.
+func (w *Watcher) SetDebounceWindow(window time.Duration) {
+	w.debounceNs.Store(int64(window))
+}
+
+// Current returns the most recently successfully parsed tree, or nil if no
+// parse has ever succeeded.
+func (w *Watcher) Current() *Config {
+	return w.current.Load()
+}
+
+// OnChange registers fn to be called after every reload attempt, successful
+// or not. On success, err is nil and new is the freshly parsed tree. On
+// failure, err is non-nil, new is nil, and old is still whatever Current()
+// returns (the last-good tree, unchanged).
+//
+// Since New's initial parse has already happened by the time it returns,
+// fn is invoked immediately with that result, and then again for every
+// reload after. This also covers a subscriber registered well after a
+// later reload completed: it is caught up with that reload's result
+// instead of silently missing it. Delivery, including this catch-up call,
+// is serialized against concurrent reloads, so fn never observes a newer
+// result before an older one. fn must not call w.OnChange, which takes the
+// same lock fn is called under, or it will deadlock.
+func (w *Watcher) OnChange(fn func(old, new *Config, err error)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.subscribers = append(w.subscribers, fn)
+	if w.notified {
+		fn(w.lastOld, w.lastNew, w.lastErr)
+	}
+}
+
+// Close stops watching path and releases the underlying fsnotify watcher.
+func (w *Watcher) Close() error {
+	w.closeOnce.Do(func() {
+		close(w.done)
+	})
+	return nil
+}
+
+func (w *Watcher) notify(old, new *Config, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.notified = true
+	w.lastOld, w.lastNew, w.lastErr = old, new, err
+	for _, sub := range w.subscribers {
+		sub(old, new, err)
+	}
+}
+
+func (w *Watcher) reload() error {
+	f, err := os.Open(w.path)
+	if err != nil {
+		w.notify(w.current.Load(), nil, err)
+		return err
+	}
+	defer f.Close()
+
+	parser, err := NewParser(f, w.parserOpts...)
+	if err != nil {
+		w.notify(w.current.Load(), nil, err)
+		return err
+	}
+
+	newConf, err := parser.ParseConfig()
+	if err != nil {
+		w.notify(w.current.Load(), nil, err)
+		return err
+	}
+
+	old := w.current.Swap(newConf)
+	w.notify(old, newConf, nil)
+	return nil
+}
+
+// run coalesces bursts of fsnotify events into single reloads and re-adds
+// the watch after a REMOVE/RENAME, which is how editors like vim and emacs
+// replace a file on save (write to a swap file, rename over the original).
+func (w *Watcher) run() {
+	defer w.fsw.Close()
+
+	var timer *time.Timer
+	var timerC <-chan time.Time
+
+	for {
+		select {
+		case <-w.done:
+			if timer != nil {
+				timer.Stop()
+			}
+			return
+
+		case event, ok := <-w.fsw.Events:
+			if !ok {
+				return
+			}
+
+			if event.Op&(fsnotify.Remove|fsnotify.Rename) != 0 {
+				w.fsw.Remove(w.path)
+				if err := w.fsw.Add(w.path); err != nil {
+					w.notify(w.current.Load(), nil, err)
+					continue
+				}
+			}
+
+			window := time.Duration(w.debounceNs.Load())
+			if timer == nil {
+				timer = time.NewTimer(window)
+			} else {
+				timer.Reset(window)
+			}
+			timerC = timer.C
+
+		case <-timerC:
+			timerC = nil
+			w.reload()
+
+		case err, ok := <-w.fsw.Errors:
+			if !ok {
+				return
+			}
+			w.notify(w.current.Load(), nil, err)
+		}
+	}
+}
@@ -0,0 +1,215 @@
+/*
+ * Copyright (c) 2021 Anthony DeDominic <adedomin@gmail.com>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy of
+ * this software and associated documentation files (the "Software"), to deal in
+ * the Software without restriction, including without limitation the rights to
+ * use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+ * the Software, and to permit persons to whom the Software is furnished to do so,
+ * subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+ * FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+ * COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+ * IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+ * CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package treetextconf
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWatcherReloadsOnChange(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.tconf")
+	writeFile(t, path, "test: 123")
+
+	w, err := New(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	w.SetDebounceWindow(10 * time.Millisecond)
+
+	changed := make(chan *Config, 1)
+	w.OnChange(func(old, new *Config, err error) {
+		if err == nil {
+			changed <- new
+		}
+	})
+
+	select {
+	case initial := <-changed:
+		assertEqual(t, initial.value[0].name, "test")
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for initial parse")
+	}
+
+	writeFile(t, path, "other: 456")
+
+	select {
+	case newConf := <-changed:
+		assertEqual(t, newConf.value[0].name, "other")
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for reload")
+	}
+}
+
+func TestWatcherOnChangeSeesInitialParseImmediately(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.tconf")
+	writeFile(t, path, "test: 123")
+
+	w, err := New(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	// New's initial parse has already completed by the time it returns,
+	// so OnChange must invoke fn with that result synchronously, before
+	// returning, rather than requiring the caller to wait for a later
+	// notification that may never come.
+	var got *Config
+	w.OnChange(func(old, new *Config, err error) {
+		if err == nil && got == nil {
+			got = new
+		}
+	})
+	if got == nil {
+		t.Fatal("OnChange did not deliver the initial parse synchronously")
+	}
+	assertEqual(t, got.value[0].name, "test")
+}
+
+func TestWatcherOnChangeCatchesUpLateSubscriber(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.tconf")
+	writeFile(t, path, "test: 123")
+
+	w, err := New(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	w.SetDebounceWindow(10 * time.Millisecond)
+
+	seen := make(chan *Config, 1)
+	w.OnChange(func(old, new *Config, err error) {
+		if err == nil {
+			seen <- new
+		}
+	})
+	<-seen // drain the initial delivery
+
+	writeFile(t, path, "other: 456")
+	select {
+	case <-seen:
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for reload")
+	}
+
+	// A subscriber registered after that reload already completed must
+	// still be caught up with its result rather than waiting forever for
+	// a notification that already happened.
+	var late *Config
+	w.OnChange(func(old, new *Config, err error) {
+		if err == nil && late == nil {
+			late = new
+		}
+	})
+	if late == nil {
+		t.Fatal("late subscriber was not caught up with the last reload")
+	}
+	assertEqual(t, late.value[0].name, "other")
+}
+
+func TestWatcherOnChangeOrderingUnderConcurrentReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.tconf")
+	writeFile(t, path, "n: 0")
+
+	w, err := New(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	w.SetDebounceWindow(time.Millisecond)
+
+	// Register a subscriber concurrently with a burst of reloads. Its
+	// catch-up delivery in OnChange must never be reordered behind (or
+	// duplicate) a notify() broadcast racing it for the same subscriber.
+	for i := 1; i <= 200; i++ {
+		writeFile(t, path, fmt.Sprintf("n: %d", i))
+
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			last := -1
+			w.OnChange(func(old, new *Config, err error) {
+				// os.WriteFile isn't atomic, so a reload can race a
+				// write and observe a momentarily-truncated file: an
+				// empty file, or a bare partial token like "n" with no
+				// ": value" yet. Both are legitimate parses, not
+				// errors, and carry no ordering information to check
+				// here.
+				if err != nil || len(new.value) == 0 || len(new.value[0].value) == 0 {
+					return
+				}
+				n, _ := strconv.Atoi(new.value[0].value[0].name)
+				if n < last {
+					t.Errorf("subscriber saw n=%d after n=%d", n, last)
+				}
+				last = n
+			})
+		}()
+		wg.Wait()
+
+		time.Sleep(3 * time.Millisecond)
+	}
+}
+
+func TestWatcherKeepsLastGoodOnParseError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.tconf")
+	writeFile(t, path, "test: 123")
+
+	w, err := New(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	w.SetDebounceWindow(10 * time.Millisecond)
+
+	failed := make(chan error, 1)
+	w.OnChange(func(old, new *Config, err error) {
+		if err != nil {
+			failed <- err
+		}
+	})
+
+	// unterminated compound group
+	writeFile(t, path, "broken:\n  a")
+
+	select {
+	case <-failed:
+		assertEqual(t, w.Current().value[0].name, "test")
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for reload failure")
+	}
+}